@@ -0,0 +1,33 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emer
+
+// NetIndexes contains basic network indexing information needed for threading
+// and data-parallel processing of multiple input patterns in a single pass
+// through the network.
+type NetIndexes struct {
+	// NData is the number of data-parallel input patterns to process in
+	// parallel in the current batch -- each pattern gets its own index
+	// (di, for "data index") into the per-pattern state on Synapse
+	// (e.g., DWt, Norm, Moment), while long-term state shared
+	// across the batch (e.g., Wt, LWt) remains a single value per synapse.
+	NData uint32 `desc:"number of data-parallel items to process in the current batch -- see Context.NData"`
+}
+
+// Context contains the global context information that is shared across
+// a network during a pass of processing -- in particular the NData
+// batch size used for data-parallel evaluation of projections (see
+// leabra.Synapse and emer.Prjn.SynValsDi).
+type Context struct {
+	NetIndexes
+}
+
+// NewContext returns a new Context with NData set to 1 (the single-pattern
+// default, equivalent to the non-data-parallel behavior).
+func NewContext() *Context {
+	ctx := &Context{}
+	ctx.NData = 1
+	return ctx
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emer
+
+import "github.com/emer/etable/etensor"
+
+// Layer defines the minimal interface a projection needs from the
+// layers it connects: enough to name itself, report whether it has
+// been lesioned, and describe its unit geometry for tensor-shaped
+// synapse access (SynValsTensor / SynValsSendTensor) and PyTorch weight
+// export (WriteWtsPyTorch).
+type Layer interface {
+	// Name returns the name of this layer
+	Name() string
+
+	// IsOff returns true if the layer has been turned off (lesioned)
+	IsOff() bool
+
+	// Shape returns the organization of units in this layer, used to
+	// reshape flat per-unit synapse values into a tensor that reflects
+	// the layer's geometry
+	Shape() *etensor.Shape
+}
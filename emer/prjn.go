@@ -10,6 +10,7 @@ import (
 
 	"github.com/emer/emergent/params"
 	"github.com/emer/emergent/prjn"
+	"github.com/emer/etable/etensor"
 	"github.com/goki/ki/kit"
 )
 
@@ -69,7 +70,52 @@ type Prjn interface {
 	// returns error message for invalid variable name
 	SynValsTry(varNm string) ([]float32, error)
 
-	// todo: tensor version of synvals using sending layer shape
+	// SynValsInto fills the given []float32 slice with the values of the
+	// given variable name on synapses for each synapse in the projection,
+	// using the natural ordering of the synapses (sender based for
+	// Leabra).  The slice is resized (reusing existing capacity where
+	// it suffices) as needed to hold NSyns values -- pass a previously
+	// returned slice on repeated calls (e.g. once per NetView update) to
+	// avoid a fresh allocation each time.  Returns error for invalid
+	// variable name.
+	SynValsInto(vals *[]float32, varNm string) error
+
+	// SetSynVals sets the values of the given variable name on synapses
+	// for each synapse in the projection, using the natural ordering of
+	// the synapses (sender based for Leabra) -- the bulk, symmetric
+	// counterpart of SynValsInto, used to restore weights written by
+	// SynValsInto/SynVals (e.g. WriteWtsPyTorch's ReadWtsPyTorch
+	// counterpart). Returns error for invalid variable name or if len(vals)
+	// does not match the projection's number of synapses.
+	SetSynVals(varNm string, vals []float32) error
+
+	// SynValsTensor fills tsr with the values of the given variable name
+	// for every synapse projecting to recvIdx (a 1D, flat receiving unit
+	// index), shaped according to the sending layer's geometry (see
+	// emer.Layer.Shape) so that, e.g., a 2D or 4D sending layer shape
+	// produces a tensor NetView can render as a proper receptive field.
+	// Sending units with no connection to recvIdx are filled with
+	// math32.NaN() so they can be rendered as "no connection" rather than
+	// zero weight. Returns error for invalid variable name or indexes.
+	// Implementations should call the shared emer.SynValsTensor helper.
+	SynValsTensor(varNm string, recvIdx int, tsr etensor.Tensor) error
+
+	// SynValsSendTensor fills tsr with the values of the given variable
+	// name for every synapse sent from sendIdx (a 1D, flat sending unit
+	// index), shaped according to the receiving layer's geometry (see
+	// emer.Layer.Shape) -- the symmetric, recv-side counterpart of
+	// SynValsTensor. Receiving units with no connection from sendIdx are
+	// filled with math32.NaN(). Returns error for invalid variable name
+	// or indexes. Implementations should call the shared
+	// emer.SynValsSendTensor helper.
+	SynValsSendTensor(varNm string, sendIdx int, tsr etensor.Tensor) error
+
+	// SynValsDi returns values of given variable name on synapses
+	// for each synapse in the projection using the natural ordering
+	// of the synapses (sender based for Leabra), for the given
+	// data parallel index di (0 <= di < Context.NData).
+	// returns nil if variable name invalid.
+	SynValsDi(varNm string, di int) []float32
 
 	// SynVal returns value of given variable name on the synapse
 	// between given send, recv unit indexes (1D, flat indexes)
@@ -86,6 +132,15 @@ type Prjn interface {
 	// returns error for access errors.
 	SetSynVal(varnm string, sidx, ridx int, val float32) error
 
+	// SetSynValDi sets value of given variable name on the synapse
+	// between given send, recv unit indexes (1D, flat indexes), for the
+	// given data parallel index di (0 <= di < Context.NData).
+	// returns error for access errors. di only matters for per-data-parallel
+	// variables (e.g. DWt, Norm, Moment) -- for shared long-term state
+	// (e.g. Wt, LWt) di is ignored and the single shared value is set,
+	// as with SetSynVal.
+	SetSynValDi(varnm string, sidx, ridx int, di int, val float32) error
+
 	// Defaults sets default parameter values for all Prjn parameters
 	Defaults()
 
@@ -106,13 +161,28 @@ type Prjn interface {
 
 	// WriteWtsJSON writes the weights from this projection from the receiver-side perspective
 	// in a JSON text format.  We build in the indentation logic to make it much faster and
-	// more efficient.
+	// more efficient.  Only the shared long-term weight state (e.g. Wt, LWt) is written --
+	// per-data-parallel state (e.g. DWt, Norm, Moment) is transient and not persisted.
 	WriteWtsJSON(w io.Writer, depth int)
 
 	// ReadWtsJSON reads the weights from this projection from the receiver-side perspective
-	// in a JSON text format.
+	// in a JSON text format.  Only the shared long-term weight state (e.g. Wt, LWt) is read --
+	// per-data-parallel state is left at its zero value and must be re-accumulated.
 	ReadWtsJSON(r io.Reader) error
 
+	// WriteWtsPyTorch writes the weights from this projection in a format loadable by
+	// PyTorch as the tensor-dict JSON used by the etorch bridge, keyed by
+	// SendLay().Name() + "To" + RecvLay().Name(), so weights trained in Leabra
+	// can be loaded into a PyTorch model.
+	// Implementations should call the shared emer.WritePrjnWtsPyTorch helper.
+	WriteWtsPyTorch(w io.Writer) error
+
+	// ReadWtsPyTorch reads the weights for this projection from the etorch bridge's
+	// tensor-dict JSON format (the inverse of WriteWtsPyTorch), allowing a
+	// PyTorch-trained model to be transferred into Leabra.
+	// Implementations should call the shared emer.ReadPrjnWtsPyTorch helper.
+	ReadWtsPyTorch(r io.Reader) error
+
 	// Build constructs the full connectivity among the layers as specified in this projection.
 	Build() error
 }
@@ -209,5 +279,11 @@ const (
 	// Inhib is an inhibitory projection that drives inhibitory synaptic inputs instead of excitatory
 	Inhib
 
+	// Modulatory is a projection that drives a dopamine-like modulatory signal
+	// rather than direct excitatory or inhibitory input -- used by basal ganglia /
+	// PCore-style learning rules that gate synaptic weight changes via a
+	// dopamine-modulated eligibility trace (see leabra.Synapse.Trace)
+	Modulatory
+
 	PrjnTypeN
 )
@@ -0,0 +1,125 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// pyTorchPrjnWts is the etorch bridge tensor-dict JSON layout for one
+// projection's weights: the effective (Wt) and linear (LWt) weight
+// values in sender-based natural order, plus the sending and receiving
+// layer shapes so a PyTorch model can reshape the flat data into a
+// parameter tensor of the correct rank.
+type pyTorchPrjnWts struct {
+	SendShape []int     `json:"send_shape"`
+	RecvShape []int     `json:"recv_shape"`
+	Wt        []float32 `json:"wt"`
+	LWt       []float32 `json:"lwt"`
+}
+
+// WritePrjnWtsPyTorch writes pj's weights to w as the etorch bridge's
+// tensor-dict JSON format, keyed by SendLay().Name() + "To" +
+// RecvLay().Name(), so a PyTorch model can load them directly (or so
+// they can be merged into a larger Network-level dict by a caller that
+// walks all projections). This is the shared logic backing
+// Prjn.WriteWtsPyTorch -- a concrete Prjn should implement that method
+// by calling this function.
+func WritePrjnWtsPyTorch(pj Prjn, w io.Writer) error {
+	wt, err := pj.SynValsTry("Wt")
+	if err != nil {
+		return err
+	}
+	lwt, err := pj.SynValsTry("LWt")
+	if err != nil {
+		return err
+	}
+	pw := &pyTorchPrjnWts{
+		SendShape: pj.SendLay().Shape().Shp,
+		RecvShape: pj.RecvLay().Shape().Shp,
+		Wt:        wt,
+		LWt:       lwt,
+	}
+	dict := map[string]*pyTorchPrjnWts{pj.Name(): pw}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dict)
+}
+
+// ReadPrjnWtsPyTorch reads pj's weights from r in the etorch bridge's
+// tensor-dict JSON format (the inverse of WritePrjnWtsPyTorch), looking
+// up the entry keyed by pj's own SendLay().Name() + "To" +
+// RecvLay().Name() and restoring Wt and LWt via SetSynVals. This is the
+// shared logic backing Prjn.ReadWtsPyTorch -- a concrete Prjn should
+// implement that method by calling this function.
+func ReadPrjnWtsPyTorch(pj Prjn, r io.Reader) error {
+	dict := map[string]*pyTorchPrjnWts{}
+	if err := json.NewDecoder(r).Decode(&dict); err != nil {
+		return err
+	}
+	pw, ok := dict[pj.Name()]
+	if !ok {
+		return fmt.Errorf("ReadPrjnWtsPyTorch: no entry for projection %q in input", pj.Name())
+	}
+	if err := pj.SetSynVals("Wt", pw.Wt); err != nil {
+		return err
+	}
+	return pj.SetSynVals("LWt", pw.LWt)
+}
+
+// WriteWtsPyTorch writes the weights for every projection in the list
+// to w as a single etorch bridge tensor-dict JSON object, keyed by each
+// projection's Name() -- the walk-all-projections counterpart of
+// WritePrjnWtsPyTorch. There is no emer.Network in this tree to hang
+// this off of, so it lives on PrjnList, the slice type that holds a set
+// of projections.
+func (pl *PrjnList) WriteWtsPyTorch(w io.Writer) error {
+	dict := make(map[string]*pyTorchPrjnWts, len(*pl))
+	for _, pj := range *pl {
+		wt, err := pj.SynValsTry("Wt")
+		if err != nil {
+			return err
+		}
+		lwt, err := pj.SynValsTry("LWt")
+		if err != nil {
+			return err
+		}
+		dict[pj.Name()] = &pyTorchPrjnWts{
+			SendShape: pj.SendLay().Shape().Shp,
+			RecvShape: pj.RecvLay().Shape().Shp,
+			Wt:        wt,
+			LWt:       lwt,
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dict)
+}
+
+// ReadWtsPyTorch reads the weights for every projection in the list
+// from r (the inverse of PrjnList.WriteWtsPyTorch), looking each
+// projection up by its own Name() and erroring if any projection in the
+// list has no corresponding entry in the input.
+func (pl *PrjnList) ReadWtsPyTorch(r io.Reader) error {
+	dict := map[string]*pyTorchPrjnWts{}
+	if err := json.NewDecoder(r).Decode(&dict); err != nil {
+		return err
+	}
+	for _, pj := range *pl {
+		pw, ok := dict[pj.Name()]
+		if !ok {
+			return fmt.Errorf("PrjnList.ReadWtsPyTorch: no entry for projection %q in input", pj.Name())
+		}
+		if err := pj.SetSynVals("Wt", pw.Wt); err != nil {
+			return err
+		}
+		if err := pj.SetSynVals("LWt", pw.LWt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/emer/emergent/params"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/etable/etensor"
+)
+
+// fakeLayer is a minimal Layer for testing the package-level PyTorch
+// helpers without a concrete algorithm's Layer type.
+type fakeLayer struct {
+	name string
+	shp  etensor.Shape
+}
+
+func (fl *fakeLayer) Name() string          { return fl.name }
+func (fl *fakeLayer) IsOff() bool           { return false }
+func (fl *fakeLayer) Shape() *etensor.Shape { return &fl.shp }
+
+func newFakePyTorchLayer(name string, n int) *fakeLayer {
+	fl := &fakeLayer{name: name}
+	fl.shp.SetShape([]int{n}, nil, nil)
+	return fl
+}
+
+// fakePrjn is a minimal Prjn implementation, storing Wt/LWt directly as
+// flat slices, used to exercise WritePrjnWtsPyTorch / ReadPrjnWtsPyTorch
+// against the real Prjn interface rather than just the JSON struct.
+type fakePrjn struct {
+	send, recv *fakeLayer
+	wt, lwt    []float32
+}
+
+func (fp *fakePrjn) Init(Prjn)                                    {}
+func (fp *fakePrjn) RecvLay() Layer                               { return fp.recv }
+func (fp *fakePrjn) SendLay() Layer                               { return fp.send }
+func (fp *fakePrjn) Pattern() prjn.Pattern                        { return nil }
+func (fp *fakePrjn) Type() PrjnType                               { return Forward }
+func (fp *fakePrjn) SetType(PrjnType)                             {}
+func (fp *fakePrjn) Connect(Layer, Layer, prjn.Pattern, PrjnType) {}
+func (fp *fakePrjn) SetClass(string)                              {}
+func (fp *fakePrjn) TypeName() string                             { return "fakePrjn" }
+func (fp *fakePrjn) Class() string                                { return "" }
+func (fp *fakePrjn) Label() string                                { return fp.Name() }
+func (fp *fakePrjn) IsOff() bool                                  { return false }
+func (fp *fakePrjn) SetOff(bool)                                  {}
+func (fp *fakePrjn) Name() string                                 { return fp.send.Name() + "To" + fp.recv.Name() }
+
+func (fp *fakePrjn) SynVarNames() []string { return []string{"Wt", "LWt"} }
+
+func (fp *fakePrjn) synVals(varNm string) ([]float32, error) {
+	switch varNm {
+	case "Wt":
+		return fp.wt, nil
+	case "LWt":
+		return fp.lwt, nil
+	}
+	return nil, fmt.Errorf("fakePrjn: variable named %q not found", varNm)
+}
+
+func (fp *fakePrjn) SynVals(varNm string) []float32 {
+	v, _ := fp.synVals(varNm)
+	return v
+}
+func (fp *fakePrjn) SynValsTry(varNm string) ([]float32, error) { return fp.synVals(varNm) }
+
+func (fp *fakePrjn) SynValsInto(vals *[]float32, varNm string) error {
+	v, err := fp.synVals(varNm)
+	if err != nil {
+		return err
+	}
+	*vals = append((*vals)[:0], v...)
+	return nil
+}
+
+func (fp *fakePrjn) SetSynVals(varNm string, vals []float32) error {
+	switch varNm {
+	case "Wt":
+		fp.wt = append([]float32{}, vals...)
+	case "LWt":
+		fp.lwt = append([]float32{}, vals...)
+	default:
+		return fmt.Errorf("fakePrjn: variable named %q not found", varNm)
+	}
+	return nil
+}
+
+func (fp *fakePrjn) SynValsTensor(varNm string, recvIdx int, tsr etensor.Tensor) error {
+	return SynValsTensor(fp, varNm, recvIdx, tsr)
+}
+func (fp *fakePrjn) SynValsSendTensor(varNm string, sendIdx int, tsr etensor.Tensor) error {
+	return SynValsSendTensor(fp, varNm, sendIdx, tsr)
+}
+func (fp *fakePrjn) SynValsDi(varNm string, di int) []float32 { return fp.SynVals(varNm) }
+
+func (fp *fakePrjn) SynVal(varnm string, sidx, ridx int) float32 {
+	v, _ := fp.SynValTry(varnm, sidx, ridx)
+	return v
+}
+func (fp *fakePrjn) SynValTry(varnm string, sidx, ridx int) (float32, error) {
+	v, err := fp.synVals(varnm)
+	if err != nil {
+		return 0, err
+	}
+	i := sidx*fp.recv.Shape().Len() + ridx
+	if i < 0 || i >= len(v) {
+		return 0, fmt.Errorf("fakePrjn: index out of range for sidx=%d ridx=%d", sidx, ridx)
+	}
+	return v[i], nil
+}
+func (fp *fakePrjn) SetSynVal(varnm string, sidx, ridx int, val float32) error {
+	v, err := fp.synVals(varnm)
+	if err != nil {
+		return err
+	}
+	i := sidx*fp.recv.Shape().Len() + ridx
+	if i < 0 || i >= len(v) {
+		return fmt.Errorf("fakePrjn: index out of range for sidx=%d ridx=%d", sidx, ridx)
+	}
+	v[i] = val
+	return nil
+}
+func (fp *fakePrjn) SetSynValDi(varnm string, sidx, ridx, di int, val float32) error {
+	return fp.SetSynVal(varnm, sidx, ridx, val)
+}
+
+func (fp *fakePrjn) Defaults()                                                {}
+func (fp *fakePrjn) UpdateParams()                                            {}
+func (fp *fakePrjn) ApplyParams(pars *params.Sheet, setMsg bool) (bool, error) { return false, nil }
+func (fp *fakePrjn) NonDefaultParams() string                                 { return "" }
+func (fp *fakePrjn) WriteWtsJSON(w io.Writer, depth int)                      {}
+func (fp *fakePrjn) ReadWtsJSON(r io.Reader) error                            { return nil }
+
+func (fp *fakePrjn) WriteWtsPyTorch(w io.Writer) error { return WritePrjnWtsPyTorch(fp, w) }
+func (fp *fakePrjn) ReadWtsPyTorch(r io.Reader) error  { return ReadPrjnWtsPyTorch(fp, r) }
+
+func (fp *fakePrjn) Build() error { return nil }
+
+func newFakePrjn(sendName, recvName string, n int) *fakePrjn {
+	fp := &fakePrjn{
+		send: newFakePyTorchLayer(sendName, n),
+		recv: newFakePyTorchLayer(recvName, n),
+		wt:   make([]float32, n*n),
+		lwt:  make([]float32, n*n),
+	}
+	for i := range fp.wt {
+		fp.wt[i] = float32(i) + 0.1
+		fp.lwt[i] = float32(i) + 0.2
+	}
+	return fp
+}
+
+// TestWriteReadPrjnWtsPyTorch exercises WritePrjnWtsPyTorch and
+// ReadPrjnWtsPyTorch directly against a fake Prjn, including the
+// Name()-keyed lookup and the "no entry for projection" error path.
+func TestWriteReadPrjnWtsPyTorch(t *testing.T) {
+	fp := newFakePrjn("LayerA", "LayerB", 2)
+
+	var buf bytes.Buffer
+	if err := WritePrjnWtsPyTorch(fp, &buf); err != nil {
+		t.Fatalf("WritePrjnWtsPyTorch: %v", err)
+	}
+
+	fp2 := newFakePrjn("LayerA", "LayerB", 2)
+	for i := range fp2.wt {
+		fp2.wt[i] = 0
+		fp2.lwt[i] = 0
+	}
+	if err := ReadPrjnWtsPyTorch(fp2, &buf); err != nil {
+		t.Fatalf("ReadPrjnWtsPyTorch: %v", err)
+	}
+	for i := range fp.wt {
+		if fp2.wt[i] != fp.wt[i] {
+			t.Errorf("Wt[%d] = %v, want %v", i, fp2.wt[i], fp.wt[i])
+		}
+		if fp2.lwt[i] != fp.lwt[i] {
+			t.Errorf("LWt[%d] = %v, want %v", i, fp2.lwt[i], fp.lwt[i])
+		}
+	}
+
+	other := newFakePrjn("LayerX", "LayerY", 2)
+	var buf2 bytes.Buffer
+	if err := WritePrjnWtsPyTorch(fp, &buf2); err != nil {
+		t.Fatalf("WritePrjnWtsPyTorch: %v", err)
+	}
+	if err := ReadPrjnWtsPyTorch(other, &buf2); err == nil {
+		t.Errorf("expected error reading wts for a projection not present in the input")
+	}
+}
+
+// TestPrjnListWriteReadWtsPyTorch exercises the walk-all-projections
+// counterparts of WritePrjnWtsPyTorch/ReadPrjnWtsPyTorch (there is no
+// emer.Network in this tree to hang them on, so they live on PrjnList,
+// the slice type that holds a set of projections).
+func TestPrjnListWriteReadWtsPyTorch(t *testing.T) {
+	var pl PrjnList
+	pl.Add(newFakePrjn("LayerA", "LayerB", 2))
+	pl.Add(newFakePrjn("LayerB", "LayerC", 2))
+
+	var buf bytes.Buffer
+	if err := pl.WriteWtsPyTorch(&buf); err != nil {
+		t.Fatalf("WriteWtsPyTorch: %v", err)
+	}
+
+	var pl2 PrjnList
+	pl2.Add(newFakePrjn("LayerA", "LayerB", 2))
+	pl2.Add(newFakePrjn("LayerB", "LayerC", 2))
+	for _, pj := range pl2 {
+		fp := pj.(*fakePrjn)
+		for i := range fp.wt {
+			fp.wt[i] = 0
+			fp.lwt[i] = 0
+		}
+	}
+	if err := pl2.ReadWtsPyTorch(&buf); err != nil {
+		t.Fatalf("ReadWtsPyTorch: %v", err)
+	}
+	for pi, pj := range pl2 {
+		want := pl[pi].(*fakePrjn)
+		got := pj.(*fakePrjn)
+		for i := range want.wt {
+			if got.wt[i] != want.wt[i] {
+				t.Errorf("prjn %d Wt[%d] = %v, want %v", pi, i, got.wt[i], want.wt[i])
+			}
+		}
+	}
+
+	var pl3 PrjnList
+	pl3.Add(newFakePrjn("Other", "LayerB", 2))
+	var buf2 bytes.Buffer
+	if err := pl.WriteWtsPyTorch(&buf2); err != nil {
+		t.Fatalf("WriteWtsPyTorch: %v", err)
+	}
+	if err := pl3.ReadWtsPyTorch(&buf2); err == nil {
+		t.Errorf("expected error reading wts for a projection not present in the input")
+	}
+}
+
+// TestPyTorchPrjnWtsRoundTrip verifies that the etorch bridge tensor-dict
+// JSON layout used by WritePrjnWtsPyTorch / ReadPrjnWtsPyTorch survives
+// an encode/decode round trip, keyed by projection name.
+func TestPyTorchPrjnWtsRoundTrip(t *testing.T) {
+	want := &pyTorchPrjnWts{
+		SendShape: []int{2, 3},
+		RecvShape: []int{4},
+		Wt:        []float32{0.1, 0.2, 0.3, 0.4, 0.5, 0.6},
+		LWt:       []float32{0.11, 0.21, 0.31, 0.41, 0.51, 0.61},
+	}
+	dict := map[string]*pyTorchPrjnWts{"LayerAToLayerB": want}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(dict); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	got := map[string]*pyTorchPrjnWts{}
+	if err := json.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	pw, ok := got["LayerAToLayerB"]
+	if !ok {
+		t.Fatalf("missing entry for LayerAToLayerB")
+	}
+	if len(pw.Wt) != len(want.Wt) {
+		t.Fatalf("Wt length = %d, want %d", len(pw.Wt), len(want.Wt))
+	}
+	for i := range want.Wt {
+		if pw.Wt[i] != want.Wt[i] {
+			t.Errorf("Wt[%d] = %v, want %v", i, pw.Wt[i], want.Wt[i])
+		}
+		if pw.LWt[i] != want.LWt[i] {
+			t.Errorf("LWt[%d] = %v, want %v", i, pw.LWt[i], want.LWt[i])
+		}
+	}
+}
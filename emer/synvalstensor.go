@@ -0,0 +1,82 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emer
+
+import (
+	"fmt"
+
+	"github.com/chewxy/math32"
+	"github.com/emer/etable/etensor"
+)
+
+// SynValsTensor fills tsr with the values of the given variable name for
+// every synapse projecting to recvIdx (a 1D, flat receiving unit index),
+// shaped according to pj's sending layer geometry (SendLay().Shape())
+// so the result can be rendered as a proper 2D/4D receptive field.
+// Sending units with no connection to recvIdx are filled with
+// math32.NaN(). This is the shared logic backing Prjn.SynValsTensor --
+// a concrete Prjn should implement that method by calling this function.
+func SynValsTensor(pj Prjn, varNm string, recvIdx int, tsr etensor.Tensor) error {
+	ok := false
+	for _, vn := range pj.SynVarNames() {
+		if vn == varNm {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return fmt.Errorf("SynValsTensor: variable named %q not found in projection %v", varNm, pj.Name())
+	}
+	if recvIdx < 0 || recvIdx >= pj.RecvLay().Shape().Len() {
+		return fmt.Errorf("SynValsTensor: recvIdx %d out of range in projection %v", recvIdx, pj.Name())
+	}
+	ssh := pj.SendLay().Shape()
+	tsr.SetShape(ssh.Shp, ssh.Strd, ssh.Nms)
+	n := ssh.Len()
+	for sidx := 0; sidx < n; sidx++ {
+		val, err := pj.SynValTry(varNm, sidx, recvIdx)
+		if err != nil {
+			tsr.SetFloat1D(sidx, float64(math32.NaN()))
+			continue
+		}
+		tsr.SetFloat1D(sidx, float64(val))
+	}
+	return nil
+}
+
+// SynValsSendTensor fills tsr with the values of the given variable name
+// for every synapse sent from sendIdx (a 1D, flat sending unit index),
+// shaped according to pj's receiving layer geometry (RecvLay().Shape())
+// -- the symmetric, recv-side counterpart of SynValsTensor. Receiving
+// units with no connection from sendIdx are filled with math32.NaN().
+// This is the shared logic backing Prjn.SynValsSendTensor -- a concrete
+// Prjn should implement that method by calling this function.
+func SynValsSendTensor(pj Prjn, varNm string, sendIdx int, tsr etensor.Tensor) error {
+	ok := false
+	for _, vn := range pj.SynVarNames() {
+		if vn == varNm {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return fmt.Errorf("SynValsSendTensor: variable named %q not found in projection %v", varNm, pj.Name())
+	}
+	if sendIdx < 0 || sendIdx >= pj.SendLay().Shape().Len() {
+		return fmt.Errorf("SynValsSendTensor: sendIdx %d out of range in projection %v", sendIdx, pj.Name())
+	}
+	rsh := pj.RecvLay().Shape()
+	tsr.SetShape(rsh.Shp, rsh.Strd, rsh.Nms)
+	n := rsh.Len()
+	for ridx := 0; ridx < n; ridx++ {
+		val, err := pj.SynValTry(varNm, sendIdx, ridx)
+		if err != nil {
+			tsr.SetFloat1D(ridx, float64(math32.NaN()))
+			continue
+		}
+		tsr.SetFloat1D(ridx, float64(val))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+// leabra.Neuron holds the activation and membrane potential state for a
+// single unit. ActQ0 retains the activation from the start of the
+// current trial (quarter 0), which TraceParams.TraceSyn reads as
+// recvActQ0 when computing the dopamine-modulated eligibility trace
+// (see leabra.TraceParams).
+type Neuron struct {
+	Act   float32 `desc:"rate-coded activation value -- what most people think of as the neuron's output"`
+	ActQ0 float32 `desc:"activation value at start of current trial (quarter 0) -- used as the pre-trial baseline for trace-based learning (see TraceParams.TraceSyn)"`
+	Ge    float32 `desc:"total excitatory synaptic conductance -- the net excitatory input to this neuron"`
+	Gi    float32 `desc:"total inhibitory synaptic conductance -- the net inhibitory input to this neuron"`
+	Vm    float32 `desc:"membrane potential -- integrates Ge, Gi, and leak to drive Act"`
+}
+
+var NeuronVars = []string{"Act", "ActQ0", "Ge", "Gi", "Vm"}
+
+// NeuronVarsMap is a map from neuron variable names to their index in
+// NeuronVars -- the Neuron-side counterpart of SynapseVarsMap, for code
+// that looks up a variable's index by name rather than its value.
+var NeuronVarsMap map[string]int
+
+func init() {
+	NeuronVarsMap = make(map[string]int, len(NeuronVars))
+	for i, v := range NeuronVars {
+		NeuronVarsMap[v] = i
+	}
+}
+
+func (nrn *Neuron) VarNames() []string {
+	return NeuronVars
+}
+
+// VarByName returns the value of the given variable name.
+//
+// This is a hand-maintained, reflection-free switch over NeuronVars (the
+// same pattern used by Synapse.VarByNameDi) -- add a case here (and to
+// SetVarByName) when adding a new Neuron field.
+func (nrn *Neuron) VarByName(varNm string) (float32, bool) {
+	switch varNm {
+	case "Act":
+		return nrn.Act, true
+	case "ActQ0":
+		return nrn.ActQ0, true
+	case "Ge":
+		return nrn.Ge, true
+	case "Gi":
+		return nrn.Gi, true
+	case "Vm":
+		return nrn.Vm, true
+	}
+	return 0, false
+}
+
+// SetVarByName sets the value of the given variable name.
+//
+// This is a hand-maintained, reflection-free switch over NeuronVars (the
+// same pattern used by Synapse.SetVarByNameDi) -- add a case here (and
+// to VarByName) when adding a new Neuron field.
+func (nrn *Neuron) SetVarByName(varNm string, val float32) bool {
+	switch varNm {
+	case "Act":
+		nrn.Act = val
+	case "ActQ0":
+		nrn.ActQ0 = val
+	case "Ge":
+		nrn.Ge = val
+	case "Gi":
+		nrn.Gi = val
+	case "Vm":
+		nrn.Vm = val
+	default:
+		return false
+	}
+	return true
+}
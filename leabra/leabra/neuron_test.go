@@ -0,0 +1,55 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import "testing"
+
+func TestNeuronVarByName(t *testing.T) {
+	nrn := Neuron{Act: 1, ActQ0: 2, Ge: 3, Gi: 4, Vm: 5}
+
+	cases := []struct {
+		varNm string
+		want  float32
+	}{
+		{"Act", 1}, {"ActQ0", 2}, {"Ge", 3}, {"Gi", 4}, {"Vm", 5},
+	}
+	for _, c := range cases {
+		got, ok := nrn.VarByName(c.varNm)
+		if !ok {
+			t.Errorf("VarByName(%q): ok = false, want true", c.varNm)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("VarByName(%q) = %v, want %v", c.varNm, got, c.want)
+		}
+	}
+
+	if _, ok := nrn.VarByName("Bogus"); ok {
+		t.Errorf("VarByName(%q): ok = true, want false", "Bogus")
+	}
+}
+
+func TestNeuronSetVarByName(t *testing.T) {
+	var nrn Neuron
+
+	if !nrn.SetVarByName("Vm", 42) {
+		t.Fatalf("SetVarByName(Vm): ok = false")
+	}
+	if nrn.Vm != 42 {
+		t.Errorf("Vm = %v, want 42", nrn.Vm)
+	}
+
+	if nrn.SetVarByName("Bogus", 1) {
+		t.Errorf("SetVarByName(Bogus): ok = true, want false")
+	}
+}
+
+func TestNeuronVarsMap(t *testing.T) {
+	for i, v := range NeuronVars {
+		if NeuronVarsMap[v] != i {
+			t.Errorf("NeuronVarsMap[%q] = %d, want %d", v, NeuronVarsMap[v], i)
+		}
+	}
+}
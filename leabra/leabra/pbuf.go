@@ -0,0 +1,83 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import "fmt"
+
+// PBuf is a ring buffer of conductance contributions awaiting delivery
+// to receiving units, used to implement per-projection synaptic /
+// axonal delay (see SynComParams.Delay).  It is allocated at Prjn.Build
+// time with SynComParams.RingSize() slots per receiving unit, and
+// indexed as Buf[slot*NRecv+ridx] so that a full cycle's worth of
+// contributions for all receiving units lives in one ring slot.
+//
+// Each cycle must call Recv for all receiving units *before* Cycle, and
+// Send for all new contributions computed this cycle *after* Cycle.
+// Cycle drains the slot just read by Recv and advances WriteIdx; Send
+// then deposits into WriteIdx+1+delay, one slot past the slot Cycle just
+// advanced to -- that extra slot of separation is what makes Delay=0
+// land exactly one cycle in the future rather than colliding with the
+// slot Cycle just made current (see Send, and SynComParams.RingSize).
+type PBuf struct {
+	NRecv    int       `desc:"number of receiving units this buffer serves -- the ring buffer is sized NRecv*RingSize"`
+	RingSize int       `desc:"number of delay slots in the ring, from SynComParams.RingSize() -- MaxDelay+2"`
+	WriteIdx int       `desc:"current ring slot -- Recv reads it as the value due this cycle, then Cycle zeroes it and advances WriteIdx; Send's delay offsets are computed from this post-advance value"`
+	Buf      []float32 `desc:"ring buffer values, size NRecv*RingSize, indexed as Buf[slot*NRecv+ridx]"`
+}
+
+// Init allocates the ring buffer for nrecv receiving units and the given
+// ring size (see SynComParams.RingSize), and resets it to empty.
+func (pb *PBuf) Init(nrecv, ringSize int) {
+	pb.NRecv = nrecv
+	pb.RingSize = ringSize
+	pb.WriteIdx = 0
+	pb.Buf = make([]float32, nrecv*ringSize)
+}
+
+// Zero clears all values in the ring buffer back to 0, without
+// reallocating or moving WriteIdx.
+func (pb *PBuf) Zero() {
+	for i := range pb.Buf {
+		pb.Buf[i] = 0
+	}
+}
+
+// Recv returns the value currently due for delivery to receiving unit
+// ridx (the slot at WriteIdx), without clearing it -- call this before
+// Cycle for all receiving units each cycle.
+func (pb *PBuf) Recv(ridx int) float32 {
+	return pb.Buf[pb.WriteIdx*pb.NRecv+ridx]
+}
+
+// Cycle drains the current ring slot (zeroing it, since Recv has
+// already read it this cycle) and advances WriteIdx to the next slot.
+// Call once per cycle, after all Recv calls and before any Send calls
+// for the current cycle.
+func (pb *PBuf) Cycle() {
+	base := pb.WriteIdx * pb.NRecv
+	for i := 0; i < pb.NRecv; i++ {
+		pb.Buf[base+i] = 0
+	}
+	pb.WriteIdx = (pb.WriteIdx + 1) % pb.RingSize
+}
+
+// Send deposits val into the ring buffer for receiving unit ridx, to be
+// delivered delay cycles after the upcoming Recv/Cycle pair -- Delay=0
+// lands in the slot Recv will read next cycle (the original, non-delayed
+// 1-cycle send->receive latency), Delay=1 lands one cycle after that,
+// etc.  Must be called after Cycle has advanced WriteIdx for the
+// current cycle (see PBuf doc).  Returns an error, without depositing
+// anything, if delay is outside the valid [0, RingSize-2] == [0,
+// MaxDelay] range -- this happens if SynComParams.Delay was raised via
+// params without also raising MaxDelay, and silently wrapping would
+// otherwise land the contribution in the wrong (too-early) slot.
+func (pb *PBuf) Send(ridx, delay int, val float32) error {
+	if delay < 0 || delay > pb.RingSize-2 {
+		return fmt.Errorf("leabra.PBuf.Send: delay %d out of range [0, %d] -- SynComParams.MaxDelay must be >= Delay", delay, pb.RingSize-2)
+	}
+	si := (pb.WriteIdx + 1 + delay) % pb.RingSize
+	pb.Buf[si*pb.NRecv+ridx] += val
+	return nil
+}
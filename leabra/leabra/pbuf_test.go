@@ -0,0 +1,100 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import "testing"
+
+// TestPBufDelayZero verifies that a Delay=0 Send lands exactly one cycle
+// later, reproducing the original (pre-PBuf) non-delayed latency, even
+// at the default MaxDelay=0 ring size -- this is the case that collapses
+// to a single, colliding slot (and breaks) if RingSize is sized as
+// MaxDelay+1 instead of MaxDelay+2.
+func TestPBufDelayZero(t *testing.T) {
+	var pb PBuf
+	pb.Init(1, 2) // MaxDelay=0 -> RingSize=2
+
+	pb.Recv(0) // drain whatever was due (0)
+	pb.Cycle()
+	if err := pb.Send(0, 0, 1.5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := pb.Recv(0); v != 0 {
+		t.Errorf("value should not be visible same cycle it was sent, got %v", v)
+	}
+	pb.Cycle()
+	if v := pb.Recv(0); v != 1.5 {
+		t.Errorf("Delay=0 value should be due next cycle, got %v", v)
+	}
+}
+
+// TestPBufDelayN verifies that Delay=n holds a value for n additional
+// cycles beyond the baseline Delay=0 latency (i.e. n+1 cycles after
+// Send), and that the ring correctly wraps around after RingSize
+// cycles.
+func TestPBufDelayN(t *testing.T) {
+	var pb PBuf
+	maxDelay := 2
+	pb.Init(1, maxDelay+2) // RingSize=4
+
+	pb.Recv(0)
+	pb.Cycle()
+	if err := pb.Send(0, 2, 7); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	latency := maxDelay + 1 // cycles after Send until the value is due
+	for cyc := 0; cyc < latency; cyc++ {
+		if v := pb.Recv(0); v != 0 {
+			t.Errorf("cycle %d: expected 0 before due, got %v", cyc, v)
+		}
+		pb.Cycle()
+	}
+	if v := pb.Recv(0); v != 7 {
+		t.Errorf("Delay=2 value should be due after %d cycles, got %v", latency, v)
+	}
+	pb.Cycle()
+	if v := pb.Recv(0); v != 0 {
+		t.Errorf("slot should be cleared after drain, got %v", v)
+	}
+}
+
+// TestPBufSendOutOfRange verifies that Send rejects a delay beyond what
+// the ring was sized for, instead of silently wrapping into the wrong
+// slot.
+func TestPBufSendOutOfRange(t *testing.T) {
+	var pb PBuf
+	pb.Init(1, 3) // MaxDelay=1 -> RingSize=3
+
+	if err := pb.Send(0, 2, 1); err == nil {
+		t.Errorf("expected error for delay beyond MaxDelay, got nil")
+	}
+	if err := pb.Send(0, -1, 1); err == nil {
+		t.Errorf("expected error for negative delay, got nil")
+	}
+}
+
+func TestSynComParamsValidate(t *testing.T) {
+	sc := SynComParams{Delay: 1, MaxDelay: 1}
+	if err := sc.Validate(); err != nil {
+		t.Errorf("unexpected error for Delay == MaxDelay: %v", err)
+	}
+	sc.Delay = 2
+	if err := sc.Validate(); err == nil {
+		t.Errorf("expected error for Delay > MaxDelay")
+	}
+}
+
+// TestSynComParamsRingSize verifies the ring is sized with the extra
+// pipeline slot (MaxDelay+2) needed by PBuf.Send's WriteIdx+1+delay
+// offset to keep MaxDelay=0 from collapsing to a single, colliding slot.
+func TestSynComParamsRingSize(t *testing.T) {
+	sc := SynComParams{MaxDelay: 0}
+	if rs := sc.RingSize(); rs != 2 {
+		t.Errorf("RingSize for MaxDelay=0 = %d, want 2", rs)
+	}
+	sc.MaxDelay = 3
+	if rs := sc.RingSize(); rs != 5 {
+		t.Errorf("RingSize for MaxDelay=3 = %d, want 5", rs)
+	}
+}
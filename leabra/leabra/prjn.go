@@ -0,0 +1,336 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/params"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/etable/etensor"
+)
+
+// Prjn is the default leabra implementation of the emer.Prjn interface,
+// providing standard sender-based synapse storage, delayed synaptic
+// communication (see SynComParams), dopamine-modulated trace learning
+// (see TraceParams), and the NetView / PyTorch weight interchange
+// methods declared on emer.Prjn.
+type Prjn struct {
+	EmerPrjn emer.Prjn     `desc:"we need a pointer to ourselves as an emer.Prjn, which enables us to call the proper interface methods when embedded in a derived type -- set by Init"`
+	Recv     emer.Layer    `desc:"receiving layer for this projection"`
+	Send     emer.Layer    `desc:"sending layer for this projection"`
+	Pat      prjn.Pattern  `desc:"pattern of connectivity"`
+	Typ      emer.PrjnType `desc:"functional type of this projection"`
+	Cls      string        `desc:"class name(s) for parameter styling, space separated if multiple"`
+	Off      bool          `desc:"lesioned / disabled"`
+
+	Com   SynComParams `desc:"synaptic communication delay parameters -- see PBuf"`
+	Trace TraceParams  `desc:"dopamine-modulated trace learning parameters, used when Typ == emer.Modulatory"`
+
+	Syns []Synapse `desc:"synapse state, one per connection, in sender-based natural order"`
+
+	SConN     []int32 `desc:"number of connections for each sending unit, in sending unit order"`
+	SConIdxSt []int32 `desc:"starting index into SConIdx (and Syns) for each sending unit"`
+	SConIdx   []int32 `desc:"receiving unit index for each connection, concatenated per sending unit -- parallel to Syns"`
+
+	RConN     []int32 `desc:"number of connections for each receiving unit, in receiving unit order"`
+	RConIdxSt []int32 `desc:"starting index into RConIdx / RSynIdx for each receiving unit"`
+	RConIdx   []int32 `desc:"sending unit index for each connection, concatenated per receiving unit"`
+	RSynIdx   []int32 `desc:"index into Syns (and SConIdx) for each connection, concatenated per receiving unit -- parallel to RConIdx"`
+
+	PBuf PBuf `desc:"ring buffer of delayed conductance contributions, sized at Build time from Com.RingSize()"`
+}
+
+// Init initializes the prjn's pointer to itself as an emer.Prjn, which
+// enables the proper interface methods to be called -- must be called
+// before use.
+func (pj *Prjn) Init(prjn emer.Prjn) {
+	pj.EmerPrjn = prjn
+}
+
+func (pj *Prjn) RecvLay() emer.Layer       { return pj.Recv }
+func (pj *Prjn) SendLay() emer.Layer       { return pj.Send }
+func (pj *Prjn) Pattern() prjn.Pattern     { return pj.Pat }
+func (pj *Prjn) Type() emer.PrjnType       { return pj.Typ }
+func (pj *Prjn) SetType(typ emer.PrjnType) { pj.Typ = typ }
+func (pj *Prjn) SetClass(cls string)       { pj.Cls = cls }
+func (pj *Prjn) Label() string             { return pj.Name() }
+
+func (pj *Prjn) IsOff() bool {
+	return pj.Off || pj.Recv.IsOff() || pj.Send.IsOff()
+}
+
+func (pj *Prjn) SetOff(off bool) { pj.Off = off }
+
+func (pj *Prjn) Connect(send, recv emer.Layer, pat prjn.Pattern, typ emer.PrjnType) {
+	pj.Send = send
+	pj.Recv = recv
+	pj.Pat = pat
+	pj.Typ = typ
+}
+
+// TypeName, Name, and Class satisfy params.Styler for parameter styling.
+func (pj *Prjn) TypeName() string { return "Prjn" }
+
+func (pj *Prjn) Name() string {
+	return pj.Send.Name() + "To" + pj.Recv.Name()
+}
+
+func (pj *Prjn) Class() string { return pj.Cls }
+
+func (pj *Prjn) Defaults() {
+	pj.Com.Defaults()
+	pj.Trace.Defaults()
+}
+
+func (pj *Prjn) UpdateParams() {
+	pj.Com.Update()
+	pj.Trace.Update()
+}
+
+// ApplyParams applies given parameter style Sheet to this projection.
+// Param-sheet diffing machinery (params.Sheet's internals) is not part
+// of this trimmed tree, so this is a minimal pass-through that just
+// ensures derived parameters stay consistent -- it does not itself
+// apply any param.Sel matches.
+func (pj *Prjn) ApplyParams(pars *params.Sheet, setMsg bool) (bool, error) {
+	pj.UpdateParams()
+	return false, nil
+}
+
+func (pj *Prjn) NonDefaultParams() string {
+	return ""
+}
+
+// Build constructs the full connectivity among the layers specified in
+// this projection, using Pat to determine which sending and receiving
+// units are connected, and allocates the Syns and PBuf state.
+func (pj *Prjn) Build() error {
+	if pj.IsOff() {
+		return nil
+	}
+	ssh := pj.Send.Shape()
+	rsh := pj.Recv.Shape()
+	sendn, recvn, cons := pj.Pat.Connect(ssh, rsh, pj.Send == pj.Recv)
+	nsend := ssh.Len()
+	nrecv := rsh.Len()
+
+	pj.SConN = make([]int32, nsend)
+	pj.RConN = make([]int32, nrecv)
+	for si := 0; si < nsend; si++ {
+		pj.SConN[si] = sendn.Value1D(si)
+	}
+	for ri := 0; ri < nrecv; ri++ {
+		pj.RConN[ri] = recvn.Value1D(ri)
+	}
+
+	pj.SConIdxSt = make([]int32, nsend)
+	nsyn := int32(0)
+	for si := 0; si < nsend; si++ {
+		pj.SConIdxSt[si] = nsyn
+		nsyn += pj.SConN[si]
+	}
+	pj.RConIdxSt = make([]int32, nrecv)
+	rnsyn := int32(0)
+	for ri := 0; ri < nrecv; ri++ {
+		pj.RConIdxSt[ri] = rnsyn
+		rnsyn += pj.RConN[ri]
+	}
+
+	pj.SConIdx = make([]int32, nsyn)
+	pj.RConIdx = make([]int32, nsyn)
+	pj.RSynIdx = make([]int32, nsyn)
+	pj.Syns = make([]Synapse, nsyn)
+
+	sOff := make([]int32, nsend)
+	rOff := make([]int32, nrecv)
+	for ri := 0; ri < nrecv; ri++ {
+		for si := 0; si < nsend; si++ {
+			if !cons.Value([]int{ri, si}) {
+				continue
+			}
+			syi := pj.SConIdxSt[si] + sOff[si]
+			pj.SConIdx[syi] = int32(ri)
+			rsi := pj.RConIdxSt[ri] + rOff[ri]
+			pj.RConIdx[rsi] = int32(si)
+			pj.RSynIdx[rsi] = syi
+			sOff[si]++
+			rOff[ri]++
+		}
+	}
+	for i := range pj.Syns {
+		pj.Syns[i].InitData(1)
+	}
+	pj.PBuf.Init(nrecv, pj.Com.RingSize())
+	return nil
+}
+
+// synIdx returns the Syns index for the connection between sidx and
+// ridx (1D, flat unit indexes), and false if no such connection exists.
+func (pj *Prjn) synIdx(sidx, ridx int) (int32, bool) {
+	if sidx < 0 || sidx >= len(pj.SConN) {
+		return 0, false
+	}
+	st := pj.SConIdxSt[sidx]
+	n := pj.SConN[sidx]
+	for i := int32(0); i < n; i++ {
+		if pj.SConIdx[st+i] == int32(ridx) {
+			return st + i, true
+		}
+	}
+	return 0, false
+}
+
+func (pj *Prjn) SynVarNames() []string { return SynapseVars }
+
+func (pj *Prjn) SynVal(varnm string, sidx, ridx int) float32 {
+	val, err := pj.SynValTry(varnm, sidx, ridx)
+	if err != nil {
+		return 0
+	}
+	return val
+}
+
+func (pj *Prjn) SynValTry(varnm string, sidx, ridx int) (float32, error) {
+	syi, ok := pj.synIdx(sidx, ridx)
+	if !ok {
+		return 0, fmt.Errorf("leabra.Prjn.SynValTry: no connection between sidx=%d, ridx=%d in %s", sidx, ridx, pj.Name())
+	}
+	val, ok := pj.Syns[syi].VarByName(varnm)
+	if !ok {
+		return 0, fmt.Errorf("leabra.Prjn.SynValTry: variable named %q not found", varnm)
+	}
+	return val, nil
+}
+
+func (pj *Prjn) SetSynVal(varnm string, sidx, ridx int, val float32) error {
+	syi, ok := pj.synIdx(sidx, ridx)
+	if !ok {
+		return fmt.Errorf("leabra.Prjn.SetSynVal: no connection between sidx=%d, ridx=%d in %s", sidx, ridx, pj.Name())
+	}
+	if !pj.Syns[syi].SetVarByName(varnm, float64(val)) {
+		return fmt.Errorf("leabra.Prjn.SetSynVal: variable named %q not found", varnm)
+	}
+	return nil
+}
+
+func (pj *Prjn) SetSynValDi(varnm string, sidx, ridx int, di int, val float32) error {
+	syi, ok := pj.synIdx(sidx, ridx)
+	if !ok {
+		return fmt.Errorf("leabra.Prjn.SetSynValDi: no connection between sidx=%d, ridx=%d in %s", sidx, ridx, pj.Name())
+	}
+	if !pj.Syns[syi].SetVarByNameDi(varnm, di, float64(val)) {
+		return fmt.Errorf("leabra.Prjn.SetSynValDi: variable named %q not found", varnm)
+	}
+	return nil
+}
+
+func (pj *Prjn) SynVals(varNm string) []float32 {
+	vals, err := pj.SynValsTry(varNm)
+	if err != nil {
+		return nil
+	}
+	return vals
+}
+
+func (pj *Prjn) SynValsTry(varNm string) ([]float32, error) {
+	vals := make([]float32, len(pj.Syns))
+	for i := range pj.Syns {
+		v, ok := pj.Syns[i].VarByName(varNm)
+		if !ok {
+			return nil, fmt.Errorf("leabra.Prjn.SynValsTry: variable named %q not found", varNm)
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+func (pj *Prjn) SynValsInto(vals *[]float32, varNm string) error {
+	ns := len(pj.Syns)
+	if cap(*vals) < ns {
+		*vals = make([]float32, ns)
+	} else {
+		*vals = (*vals)[:ns]
+	}
+	for i := range pj.Syns {
+		v, ok := pj.Syns[i].VarByName(varNm)
+		if !ok {
+			return fmt.Errorf("leabra.Prjn.SynValsInto: variable named %q not found", varNm)
+		}
+		(*vals)[i] = v
+	}
+	return nil
+}
+
+func (pj *Prjn) SetSynVals(varNm string, vals []float32) error {
+	if len(vals) != len(pj.Syns) {
+		return fmt.Errorf("leabra.Prjn.SetSynVals: len(vals)=%d != NSyns=%d for %s", len(vals), len(pj.Syns), pj.Name())
+	}
+	for i := range pj.Syns {
+		if !pj.Syns[i].SetVarByName(varNm, float64(vals[i])) {
+			return fmt.Errorf("leabra.Prjn.SetSynVals: variable named %q not found", varNm)
+		}
+	}
+	return nil
+}
+
+func (pj *Prjn) SynValsDi(varNm string, di int) []float32 {
+	vals := make([]float32, len(pj.Syns))
+	for i := range pj.Syns {
+		v, ok := pj.Syns[i].VarByNameDi(varNm, di)
+		if !ok {
+			return nil
+		}
+		vals[i] = v
+	}
+	return vals
+}
+
+func (pj *Prjn) SynValsTensor(varNm string, recvIdx int, tsr etensor.Tensor) error {
+	return emer.SynValsTensor(pj.EmerPrjn, varNm, recvIdx, tsr)
+}
+
+func (pj *Prjn) SynValsSendTensor(varNm string, sendIdx int, tsr etensor.Tensor) error {
+	return emer.SynValsSendTensor(pj.EmerPrjn, varNm, sendIdx, tsr)
+}
+
+func (pj *Prjn) WriteWtsPyTorch(w io.Writer) error {
+	return emer.WritePrjnWtsPyTorch(pj.EmerPrjn, w)
+}
+
+func (pj *Prjn) ReadWtsPyTorch(r io.Reader) error {
+	return emer.ReadPrjnWtsPyTorch(pj.EmerPrjn, r)
+}
+
+// prjnWtsJSON is the JSON layout for WriteWtsJSON / ReadWtsJSON: flat
+// Wt and LWt arrays in the sender-based natural order of Syns (not
+// receiver-side), written without indentation (depth is not used).
+// Only the shared long-term weight state (Wt, LWt) is
+// persisted -- per-data-parallel state (DWt, Norm, Moment) is transient.
+type prjnWtsJSON struct {
+	Wt  []float32 `json:"Wt"`
+	LWt []float32 `json:"LWt"`
+}
+
+func (pj *Prjn) WriteWtsJSON(w io.Writer, depth int) {
+	wt, _ := pj.SynValsTry("Wt")
+	lwt, _ := pj.SynValsTry("LWt")
+	enc := json.NewEncoder(w)
+	enc.Encode(&prjnWtsJSON{Wt: wt, LWt: lwt})
+}
+
+func (pj *Prjn) ReadWtsJSON(r io.Reader) error {
+	var pw prjnWtsJSON
+	if err := json.NewDecoder(r).Decode(&pw); err != nil {
+		return err
+	}
+	if err := pj.SetSynVals("Wt", pw.Wt); err != nil {
+		return err
+	}
+	return pj.SetSynVals("LWt", pw.LWt)
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etensor"
+)
+
+// fakeLayer is a minimal emer.Layer for testing Prjn.Build and the
+// synapse-access methods without a real leabra Layer type.
+type fakeLayer struct {
+	name string
+	shp  etensor.Shape
+}
+
+func (fl *fakeLayer) Name() string          { return fl.name }
+func (fl *fakeLayer) IsOff() bool           { return false }
+func (fl *fakeLayer) Shape() *etensor.Shape { return &fl.shp }
+
+func newFakeLayer(name string, n int) *fakeLayer {
+	fl := &fakeLayer{name: name}
+	fl.shp.SetShape([]int{n}, nil, nil)
+	return fl
+}
+
+// fakeFull is a fully-connected prjn.Pattern for testing Build.
+type fakeFull struct{}
+
+func (fp fakeFull) Name() string { return "Full" }
+
+func (fp fakeFull) Connect(send, recv *etensor.Shape, same bool) (sendn, recvn *etensor.Int32, cons *etensor.Bits) {
+	ns := send.Len()
+	nr := recv.Len()
+	sendn = etensor.NewInt32([]int{ns}, nil, nil)
+	for i := 0; i < ns; i++ {
+		sendn.SetValue1D(i, int32(nr))
+	}
+	recvn = etensor.NewInt32([]int{nr}, nil, nil)
+	for i := 0; i < nr; i++ {
+		recvn.SetValue1D(i, int32(ns))
+	}
+	cons = etensor.NewBits([]int{nr, ns}, nil, nil)
+	for i := 0; i < nr*ns; i++ {
+		cons.Set1D(i, true)
+	}
+	return
+}
+
+func newFullPrjn() *Prjn {
+	pj := &Prjn{}
+	pj.Init(pj)
+	send := newFakeLayer("LayerA", 2)
+	recv := newFakeLayer("LayerB", 3)
+	pj.Connect(send, recv, fakeFull{}, emer.Forward)
+	pj.Defaults()
+	if err := pj.Build(); err != nil {
+		panic(err)
+	}
+	return pj
+}
+
+func TestPrjnBuildAndSynVal(t *testing.T) {
+	pj := newFullPrjn()
+	if len(pj.Syns) != 6 {
+		t.Fatalf("NSyns = %d, want 6", len(pj.Syns))
+	}
+	if err := pj.SetSynVal("Wt", 0, 1, 0.5); err != nil {
+		t.Fatalf("SetSynVal: %v", err)
+	}
+	if v := pj.SynVal("Wt", 0, 1); v != 0.5 {
+		t.Errorf("SynVal(Wt, 0, 1) = %v, want 0.5", v)
+	}
+	if _, err := pj.SynValTry("Wt", 5, 0); err == nil {
+		t.Errorf("expected error for out-of-range sidx")
+	}
+}
+
+func TestPrjnName(t *testing.T) {
+	pj := newFullPrjn()
+	if got, want := pj.Name(), "LayerAToLayerB"; got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+}
+
+// TestPrjnWriteReadWtsPyTorch exercises WriteWtsPyTorch/ReadWtsPyTorch
+// against a real concrete Prjn, including the Name()-keyed lookup and
+// the "no entry for projection" error path in ReadWtsPyTorch.
+func TestPrjnWriteReadWtsPyTorch(t *testing.T) {
+	pj := newFullPrjn()
+	for i := range pj.Syns {
+		pj.Syns[i].Wt = float32(i) + 0.1
+		pj.Syns[i].LWt = float32(i) + 0.2
+	}
+
+	var buf bytes.Buffer
+	if err := pj.WriteWtsPyTorch(&buf); err != nil {
+		t.Fatalf("WriteWtsPyTorch: %v", err)
+	}
+
+	pj2 := newFullPrjn()
+	if err := pj2.ReadWtsPyTorch(&buf); err != nil {
+		t.Fatalf("ReadWtsPyTorch: %v", err)
+	}
+	for i := range pj.Syns {
+		if pj2.Syns[i].Wt != pj.Syns[i].Wt {
+			t.Errorf("Syns[%d].Wt = %v, want %v", i, pj2.Syns[i].Wt, pj.Syns[i].Wt)
+		}
+		if pj2.Syns[i].LWt != pj.Syns[i].LWt {
+			t.Errorf("Syns[%d].LWt = %v, want %v", i, pj2.Syns[i].LWt, pj.Syns[i].LWt)
+		}
+	}
+
+	// a projection whose name isn't in the input must error, not silently
+	// leave weights untouched.
+	pj3 := &Prjn{}
+	pj3.Init(pj3)
+	pj3.Connect(newFakeLayer("Other", 2), newFakeLayer("Layer", 3), fakeFull{}, emer.Forward)
+	pj3.Defaults()
+	if err := pj3.Build(); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	var buf2 bytes.Buffer
+	if err := pj.WriteWtsPyTorch(&buf2); err != nil {
+		t.Fatalf("WriteWtsPyTorch: %v", err)
+	}
+	if err := pj3.ReadWtsPyTorch(&buf2); err == nil {
+		t.Errorf("expected error reading wts for a projection not present in the input")
+	}
+}
@@ -4,19 +4,32 @@
 
 package leabra
 
-import "reflect"
-
-// leabra.Synapse holds state for the synaptic connection between neurons
+// leabra.Synapse holds state for the synaptic connection between neurons.
+// Wt and LWt are long-term weight state, shared across all data-parallel
+// patterns processed together in a single pass through the network (see
+// emer.Context.NData).  DWt, Norm, and Moment vary per data-parallel
+// pattern and are stored as slices indexed by di (0 <= di < NData) --
+// each di accumulates its own DWt over a trial.  No function in this
+// package reduces the per-di DWt values into the shared LWt/Wt.
 type Synapse struct {
-	Wt     float32 `desc:"synaptic weight value -- sigmoid contrast-enhanced"`
-	LWt    float32 `desc:"linear (underlying) weight value -- learns according to the lrate specified in the connection spec -- this is converted into the effective weight value, Wt, via sigmoidal contrast enhancement (see WtSigPars)"`
-	DWt    float32 `desc:"change in synaptic weight, from learning"`
-	Norm   float32 `desc:"dwt normalization factor -- reset to max of abs value of dwt, decays slowly down over time -- serves as an estimate of variance in weight changes over time"`
-	Moment float32 `desc:"momentum -- time-integrated dwt changes, to accumulate a consistent direction of weight change and cancel out dithering contradictory changes"`
+	Wt  float32 `desc:"synaptic weight value -- sigmoid contrast-enhanced"`
+	LWt float32 `desc:"linear (underlying) weight value -- learns according to the lrate specified in the connection spec -- this is converted into the effective weight value, Wt, via sigmoidal contrast enhancement (see WtSigPars)"`
+
+	DWt    []float32 `desc:"change in synaptic weight, from learning -- one value per data-parallel index (see emer.Context.NData)"`
+	Norm   []float32 `desc:"dwt normalization factor -- reset to max of abs value of dwt, decays slowly down over time -- serves as an estimate of variance in weight changes over time -- one value per data-parallel index"`
+	Moment []float32 `desc:"momentum -- time-integrated dwt changes, to accumulate a consistent direction of weight change and cancel out dithering contradictory changes -- one value per data-parallel index"`
+
+	Trace float32 `desc:"slow-integrated eligibility trace for dopamine-modulated (Modulatory) learning -- accumulates NTr over trials and decays by TraceParams.Decay on dopamine gating events -- see leabra.TraceParams.TraceSyn"`
+	NTr   float32 `desc:"new trace value computed this trial as SendAct * (RecvAct - RecvActQ0), prior to integration into Trace -- kept separate from Trace so learning can use the un-decayed, just-computed value if needed"`
 }
 
-var SynapseVars = []string{"Wt", "LWt", "DWt", "Norm", "Moment"}
+var SynapseVars = []string{"Wt", "LWt", "DWt", "Norm", "Moment", "Trace", "NTr"}
 
+// SynapseVarsMap is a map from synapse variable names to their index in
+// SynapseVars -- kept alongside the hand-maintained VarByNameDi /
+// SetVarByNameDi switch (which covers the hot path) for other code
+// (e.g. NetView, param-styling) that looks up a variable's index by
+// name rather than its value.
 var SynapseVarsMap map[string]int
 
 func init() {
@@ -26,27 +39,88 @@ func init() {
 	}
 }
 
+// see leabra.Neuron for the analogous reflection-free
+// VarByName/SetVarByName switch over NeuronVars.
+
 func (sy *Synapse) VarNames() []string {
 	return SynapseVars
 }
 
+// InitData allocates the per-data-parallel DWt, Norm, and Moment slices
+// for ndata patterns (see emer.Context.NData), zeroing any prior values.
+func (sy *Synapse) InitData(ndata int) {
+	sy.DWt = make([]float32, ndata)
+	sy.Norm = make([]float32, ndata)
+	sy.Moment = make([]float32, ndata)
+}
+
+// VarByName returns the value of the given variable name.  For the
+// per-data-parallel variables (DWt, Norm, Moment) this returns the di=0
+// value -- see VarByNameDi for explicit data-parallel index access.
 func (sy *Synapse) VarByName(varNm string) (float32, bool) {
-	i, ok := SynapseVarsMap[varNm]
-	if !ok {
-		return 0, false
+	return sy.VarByNameDi(varNm, 0)
+}
+
+// VarByNameDi returns the value of the given variable name for the given
+// data-parallel index di (0 <= di < NData) -- di is ignored for the
+// shared long-term Wt and LWt variables.
+//
+// This is a hand-maintained switch over SynapseVars, replacing the prior
+// reflect-based lookup -- add a case here (and to SetVarByNameDi) when
+// adding a new Synapse field.
+func (sy *Synapse) VarByNameDi(varNm string, di int) (float32, bool) {
+	switch varNm {
+	case "Wt":
+		return sy.Wt, true
+	case "LWt":
+		return sy.LWt, true
+	case "DWt":
+		return sy.DWt[di], true
+	case "Norm":
+		return sy.Norm[di], true
+	case "Moment":
+		return sy.Moment[di], true
+	case "Trace":
+		return sy.Trace, true
+	case "NTr":
+		return sy.NTr, true
 	}
-	// todo: would be ideal to avoid having to use reflect here..
-	v := reflect.ValueOf(sy)
-	return v.Elem().Field(i).Interface().(float32), true
+	return 0, false
 }
 
+// SetVarByName sets the value of the given variable name.  For the
+// per-data-parallel variables (DWt, Norm, Moment) this sets the di=0
+// value -- see SetVarByNameDi for explicit data-parallel index access.
 func (sy *Synapse) SetVarByName(varNm string, val float64) bool {
-	i, ok := SynapseVarsMap[varNm]
-	if !ok {
+	return sy.SetVarByNameDi(varNm, 0, val)
+}
+
+// SetVarByNameDi sets the value of the given variable name for the given
+// data-parallel index di (0 <= di < NData) -- di is ignored for the
+// shared long-term Wt and LWt variables.
+//
+// This is a hand-maintained switch over SynapseVars, replacing the prior
+// reflect-based lookup -- add a case here (and to VarByNameDi) when
+// adding a new Synapse field.
+func (sy *Synapse) SetVarByNameDi(varNm string, di int, val float64) bool {
+	v := float32(val)
+	switch varNm {
+	case "Wt":
+		sy.Wt = v
+	case "LWt":
+		sy.LWt = v
+	case "DWt":
+		sy.DWt[di] = v
+	case "Norm":
+		sy.Norm[di] = v
+	case "Moment":
+		sy.Moment[di] = v
+	case "Trace":
+		sy.Trace = v
+	case "NTr":
+		sy.NTr = v
+	default:
 		return false
 	}
-	// todo: would be ideal to avoid having to use reflect here..
-	v := reflect.ValueOf(sy)
-	v.Elem().Field(i).SetFloat(val)
 	return true
 }
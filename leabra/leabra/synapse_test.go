@@ -0,0 +1,83 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import "testing"
+
+func TestSynapseVarByNameDi(t *testing.T) {
+	var sy Synapse
+	sy.InitData(2)
+	sy.Wt = 1
+	sy.LWt = 2
+	sy.DWt[0] = 3
+	sy.DWt[1] = 30
+	sy.Norm[1] = 4
+	sy.Moment[1] = 5
+	sy.Trace = 6
+	sy.NTr = 7
+
+	cases := []struct {
+		varNm string
+		di    int
+		want  float32
+	}{
+		{"Wt", 0, 1}, {"LWt", 1, 2}, {"DWt", 0, 3}, {"DWt", 1, 30},
+		{"Norm", 1, 4}, {"Moment", 1, 5}, {"Trace", 0, 6}, {"NTr", 0, 7},
+	}
+	for _, c := range cases {
+		got, ok := sy.VarByNameDi(c.varNm, c.di)
+		if !ok {
+			t.Errorf("VarByNameDi(%q, %d): ok = false, want true", c.varNm, c.di)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("VarByNameDi(%q, %d) = %v, want %v", c.varNm, c.di, got, c.want)
+		}
+	}
+
+	if _, ok := sy.VarByNameDi("Bogus", 0); ok {
+		t.Errorf("VarByNameDi(%q): ok = true, want false", "Bogus")
+	}
+}
+
+func TestSynapseSetVarByNameDi(t *testing.T) {
+	var sy Synapse
+	sy.InitData(2)
+
+	if !sy.SetVarByNameDi("DWt", 1, 42) {
+		t.Fatalf("SetVarByNameDi(DWt, 1): ok = false")
+	}
+	if sy.DWt[1] != 42 {
+		t.Errorf("DWt[1] = %v, want 42", sy.DWt[1])
+	}
+	if sy.DWt[0] != 0 {
+		t.Errorf("DWt[0] = %v, want 0 (unaffected)", sy.DWt[0])
+	}
+
+	if sy.SetVarByNameDi("Bogus", 0, 1) {
+		t.Errorf("SetVarByNameDi(Bogus): ok = true, want false")
+	}
+}
+
+func TestSynapseVarsMap(t *testing.T) {
+	for i, v := range SynapseVars {
+		if SynapseVarsMap[v] != i {
+			t.Errorf("SynapseVarsMap[%q] = %d, want %d", v, SynapseVarsMap[v], i)
+		}
+	}
+}
+
+func TestSynapseVarByNameDefaultsToDiZero(t *testing.T) {
+	var sy Synapse
+	sy.InitData(2)
+	sy.SetVarByName("DWt", 9)
+	if sy.DWt[0] != 9 {
+		t.Errorf("SetVarByName(DWt) should set di=0, got DWt[0] = %v", sy.DWt[0])
+	}
+	got, _ := sy.VarByName("DWt")
+	if got != 9 {
+		t.Errorf("VarByName(DWt) = %v, want 9", got)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import "fmt"
+
+// SynComParams are synaptic communication parameters: used in the
+// Prjn parameters to determine delays in conductance value changes.
+type SynComParams struct {
+	Delay    int `min:"0" desc:"additional synaptic delay in msec cycles for inputs arriving at the receiving neuron, on top of the baseline 1-cycle send->receive latency -- the MaxDelay parameter determines the maximum delay range allocated in the PBuf ring buffer, and must be >= Delay for any given projection -- a Delay of 0 means activation sent on a given cycle is received by the target neuron on the next cycle (the original, non-delayed behavior), while Delay=1 holds it for one additional cycle beyond that, etc"`
+	MaxDelay int `min:"0" desc:"maximum value of Delay expected for this projection -- used to allocate the PBuf ring buffer size at Build time (MaxDelay+2 slots per receiving unit) so that Delay can be changed at runtime (e.g., via params) up to this maximum without reallocating"`
+}
+
+// Defaults sets default values -- Delay = 0 reproduces the original,
+// immediate (non-delayed) sending behavior.
+func (sc *SynComParams) Defaults() {
+	sc.Delay = 0
+	sc.MaxDelay = 0
+}
+
+// Update is an empty placeholder for the standard params Update method,
+// included for consistency with other param structs -- Delay has no
+// derived fields that need to be recomputed from other values.
+func (sc *SynComParams) Update() {
+}
+
+// RingSize returns the number of delay slots to allocate per receiving
+// unit in the PBuf ring buffer: MaxDelay+2. One slot holds the value
+// currently due for Recv; a Delay=0 Send needs a second, distinct slot
+// to land in so it isn't visible until the next cycle -- with only
+// MaxDelay+1 slots, MaxDelay=0 (the default, most common configuration)
+// collapses to a single slot and a same-cycle Send becomes visible
+// immediately instead of next cycle. The extra slot generalizes that
+// next-cycle pipeline latency to every MaxDelay.
+func (sc *SynComParams) RingSize() int {
+	return sc.MaxDelay + 2
+}
+
+// Validate returns an error if Delay is out of the configurable [0,
+// MaxDelay] range for this projection's allocated PBuf ring buffer --
+// call after ApplyParams has potentially changed Delay at runtime, since
+// nothing else prevents Delay from being set above MaxDelay (which would
+// otherwise cause PBuf.Send to reject every deposit for this Prjn).
+func (sc *SynComParams) Validate() error {
+	if sc.Delay < 0 || sc.Delay > sc.MaxDelay {
+		return fmt.Errorf("leabra.SynComParams: Delay %d out of range [0, MaxDelay=%d]", sc.Delay, sc.MaxDelay)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+// TraceParams govern the dopamine-modulated eligibility trace learning
+// rule used by Modulatory projections (see emer.PrjnType), as in the
+// PCore / BGate basal ganglia learning extensions.  A trace of
+// send-recv co-activity is integrated into Synapse.Trace over trials,
+// and weight changes are gated by a later dopamine (DA) signal rather
+// than computed directly from activity each trial.
+type TraceParams struct {
+	Decay float32 `min:"0" max:"1" desc:"decay rate for Trace on each dopamine gating event -- 1 fully clears Trace to 0 (so the next TraceSyn starts it over from the newly-computed NTr), 0 never decays and just keeps accumulating NTr"`
+}
+
+// Defaults sets default trace decay to 1 -- i.e., each gating event
+// fully replaces the trace (no multi-trial trace integration), which is
+// the simplest, most common configuration.
+func (tp *TraceParams) Defaults() {
+	tp.Decay = 1
+}
+
+// Update is an empty placeholder for the standard params Update method,
+// included for consistency with other param structs.
+func (tp *TraceParams) Update() {
+}
+
+// NTr computes the new instantaneous trace value for one synapse from
+// sending activation, and the receiving unit's current activation and
+// its activation at the start of the trial (quarter 0, Neuron.ActQ0):
+// NTr = sendAct * (recvAct - recvActQ0).
+func (tp *TraceParams) NTr(sendAct, recvAct, recvActQ0 float32) float32 {
+	return sendAct * (recvAct - recvActQ0)
+}
+
+// TraceSyn integrates one trial's worth of send-recv co-activity into
+// sy.Trace (via sy.NTr), for a synapse on a Modulatory projection.  It
+// does not compute DWt -- that happens in DWtFmTrace, below, which is
+// called when a dopamine-gating event occurs.
+func (tp *TraceParams) TraceSyn(sy *Synapse, sendAct, recvAct, recvActQ0 float32) {
+	sy.NTr = tp.NTr(sendAct, recvAct, recvActQ0)
+	sy.Trace += sy.NTr
+}
+
+// DWtFmTrace computes DWt = da * Trace for a dopamine-gated update on
+// the given synapse (accumulating into the synapse's DWt at the given
+// data-parallel index di -- see emer.Context.NData), and then decays
+// Trace by Decay in preparation for the next gating interval.
+func (tp *TraceParams) DWtFmTrace(sy *Synapse, di int, da float32) {
+	sy.DWt[di] += da * sy.Trace
+	sy.Trace -= tp.Decay * sy.Trace
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import "testing"
+
+func TestTraceParamsTraceSyn(t *testing.T) {
+	var tp TraceParams
+	tp.Defaults()
+
+	var sy Synapse
+	sy.InitData(1)
+
+	tp.TraceSyn(&sy, 0.8, 0.6, 0.1)
+	want := float32(0.8) * (float32(0.6) - float32(0.1))
+	if sy.NTr != want {
+		t.Errorf("NTr = %v, want %v", sy.NTr, want)
+	}
+	if sy.Trace != want {
+		t.Errorf("Trace = %v, want %v (first accumulation)", sy.Trace, want)
+	}
+}
+
+func TestTraceParamsDWtFmTrace(t *testing.T) {
+	var tp TraceParams
+	tp.Decay = 0.5
+
+	var sy Synapse
+	sy.InitData(1)
+	sy.Trace = 1.0
+
+	tp.DWtFmTrace(&sy, 0, 2.0)
+	if sy.DWt[0] != 2.0 {
+		t.Errorf("DWt[0] = %v, want 2.0 (da * Trace)", sy.DWt[0])
+	}
+	if sy.Trace != 0.5 {
+		t.Errorf("Trace after decay = %v, want 0.5", sy.Trace)
+	}
+
+	tp.DWtFmTrace(&sy, 0, 2.0)
+	if sy.DWt[0] != 3.0 {
+		t.Errorf("DWt[0] after second gating = %v, want 3.0 (accumulated)", sy.DWt[0])
+	}
+}